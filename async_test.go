@@ -0,0 +1,179 @@
+package logrus
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter lets a test hold the background goroutine's out.Write call
+// open so it can deterministically fill (and overflow) the queue behind it.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+
+	mu     sync.Mutex
+	writes [][]byte
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.release
+
+	w.mu.Lock()
+	w.writes = append(w.writes, append([]byte(nil), p...))
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func (w *blockingWriter) snapshot() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	got := make([]string, len(w.writes))
+	for i, p := range w.writes {
+		got[i] = string(p)
+	}
+	return got
+}
+
+func TestAsyncWriterDropOldestEvictsOldestQueued(t *testing.T) {
+	bw := newBlockingWriter()
+	w := newAsyncWriter(bw, 2, DropOldest)
+	defer w.Close()
+
+	w.Write([]byte("a"))
+	<-bw.started // "a" is now stuck inside bw.Write, queue is empty behind it
+
+	w.Write([]byte("b"))
+	w.Write([]byte("c")) // queue full: [b, c]
+	w.Write([]byte("d")) // evicts "b", queue becomes [c, d]
+
+	if got := w.Dropped(); got != 1 {
+		t.Fatalf("Dropped() = %d, want 1", got)
+	}
+
+	close(bw.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := []string{"a", "c", "d"}
+	got := bw.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("writes = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAsyncWriterDropNewestKeepsQueuedEntries(t *testing.T) {
+	bw := newBlockingWriter()
+	w := newAsyncWriter(bw, 2, DropNewest)
+	defer w.Close()
+
+	w.Write([]byte("a"))
+	<-bw.started
+
+	w.Write([]byte("b"))
+	w.Write([]byte("c")) // queue full: [b, c]
+	w.Write([]byte("d")) // dropped, queue stays [b, c]
+
+	if got := w.Dropped(); got != 1 {
+		t.Fatalf("Dropped() = %d, want 1", got)
+	}
+
+	close(bw.release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := []string{"a", "b", "c"}
+	got := bw.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("writes = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("writes = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestAsyncWriterWriteAfterCloseDoesNotPanic(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAsyncWriter(&buf, 4, DropNewest)
+
+	w.Write([]byte("before-close"))
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Write many times after Close rather than once: the fallback to a
+	// direct, synchronous write must be deterministic once done is closed,
+	// not a coin flip with the queue send, so every one of these has to
+	// land in buf, not just most of them.
+	for i := 0; i < 50; i++ {
+		if _, err := w.Write([]byte("after-close")); err != nil {
+			t.Fatalf("Write after Close returned an error: %v", err)
+		}
+	}
+
+	if got := bytes.Count(buf.Bytes(), []byte("after-close")); got != 50 {
+		t.Fatalf("buf contains %d post-Close writes, want 50 (none should have been silently dropped into the dead queue)", got)
+	}
+}
+
+func TestAsyncWriterCloseIsIdempotent(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAsyncWriter(&buf, 1, Block)
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
+
+func TestAsyncWriterFlushWaitsForQueuedWrites(t *testing.T) {
+	var buf bytes.Buffer
+	w := newAsyncWriter(&buf, 4, Block)
+	defer w.Close()
+
+	for _, s := range []string{"a", "b", "c"} {
+		w.Write([]byte(s))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := buf.String(); got != "abc" {
+		t.Fatalf("buf = %q, want %q", got, "abc")
+	}
+}
@@ -0,0 +1,12 @@
+// +build !windows
+
+package logrus
+
+import "os"
+
+// enableColorConsole is a no-op outside of Windows. Unix terminals already
+// interpret ANSI escape sequences natively, so there's no console mode to
+// flip before TextFormatter can safely emit colors.
+func enableColorConsole(out *os.File) bool {
+	return true
+}
@@ -0,0 +1,59 @@
+package logrus
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestPrepareConsoleIgnoresNonFileOut(t *testing.T) {
+	var buf bytes.Buffer
+	logger := &Logger{Out: &buf, Formatter: new(TextFormatter)}
+
+	logger.prepareConsole()
+
+	if logger.Formatter.(*TextFormatter).DisableColors {
+		t.Fatal("prepareConsole touched DisableColors even though Out isn't a console")
+	}
+}
+
+func TestPrepareConsoleIgnoresRedirectedFile(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "logrus-console-test")
+	if err != nil {
+		t.Fatalf("CreateTemp: %v", err)
+	}
+	defer f.Close()
+
+	logger := &Logger{Out: f, Formatter: new(TextFormatter)}
+	logger.prepareConsole()
+
+	if logger.Formatter.(*TextFormatter).DisableColors {
+		t.Fatal("prepareConsole touched DisableColors for a file that isn't os.Stdout/os.Stderr")
+	}
+}
+
+// discardSink swallows every entry, so tests can point Out at os.Stdout to
+// exercise prepareConsole's console detection without actually printing
+// anything.
+type discardSink struct{}
+
+func (discardSink) Write(entry *Entry) error { return nil }
+func (discardSink) Close() error             { return nil }
+
+func TestPrepareConsoleRunsOnceFromWrite(t *testing.T) {
+	logger := &Logger{Out: os.Stdout, Sink: discardSink{}, Formatter: new(TextFormatter)}
+
+	// prepareConsole must not panic or misbehave when triggered repeatedly
+	// through write(); logger.consoleOnce is what keeps it to a single run.
+	for i := 0; i < 3; i++ {
+		if err := logger.write(&Entry{Message: "hi"}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	// write must already have spent consoleOnce on prepareConsole, so this
+	// Do has nothing left to run.
+	logger.consoleOnce.Do(func() {
+		t.Fatal("write didn't run prepareConsole through consoleOnce")
+	})
+}
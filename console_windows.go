@@ -0,0 +1,41 @@
+// +build windows
+
+package logrus
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// enableVirtualTerminalProcessing is the console mode flag that makes the
+// Windows console host interpret ANSI escape sequences instead of printing
+// them literally. It was introduced in Windows 10.
+const enableVirtualTerminalProcessing = 0x0004
+
+// enableColorConsole tries to switch out, a console handle, into virtual
+// terminal mode so that ANSI color escape codes written by TextFormatter are
+// interpreted rather than printed as raw bytes. It reports whether the
+// console can be treated as ANSI-capable afterwards. GetConsoleMode fails
+// when out isn't an actual console (e.g. it was redirected to a file or
+// piped), and SetConsoleMode fails on Windows versions that predate
+// ENABLE_VIRTUAL_TERMINAL_PROCESSING; both are treated as "not capable"
+// rather than errors.
+func enableColorConsole(out *os.File) bool {
+	handle := windows.Handle(out.Fd())
+
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return false
+	}
+
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+
+	if err := windows.SetConsoleMode(handle, mode|enableVirtualTerminalProcessing); err != nil {
+		return false
+	}
+
+	return true
+}
@@ -11,6 +11,17 @@ type Logger struct {
 	// file, or leave it default which is `os.Stdout`. You can also set this to
 	// something more adventorous, such as logging to Kafka.
 	Out io.Writer
+	// If set, entries are handed to this Sink instead of being formatted and
+	// written to Out, letting destinations that natively consume structured
+	// records (Kafka, syslog, a cloud logging API, an in-memory test sink)
+	// receive the fully-populated `*Entry` without it being serialized to
+	// bytes first. Out and Formatter remain the default when Sink is nil.
+	Sink Sink
+	// If set, consulted for every entry before it's formatted or written.
+	// Sample returning false suppresses the entry; it's how Logger protects
+	// itself and its Sink/Out from a hot loop flooding them with identical
+	// messages.
+	Sampler Sampler
 	// Hooks for the logger instance. These allow firing events based on logging
 	// levels and log entries. For example, to send errors to an error tracking
 	// service, log to StatsD or dump the core on fatal errors.
@@ -28,6 +39,16 @@ type Logger struct {
 	Level Level
 	// Used to sync writing to the log.
 	mu sync.Mutex
+	// Set by EnableAsync; non-nil once async logging is on. Wraps Sink when
+	// one is set, otherwise Out.
+	async asyncPipe
+	// Counts entries Sampler suppressed, surfaced via Stats.
+	sampled uint64
+	// Guards prepareConsole so it only ever runs once per Logger, on the
+	// first call to write (even if Sampler then suppresses that entry),
+	// regardless of whether the Logger was built via New() or as a struct
+	// literal.
+	consoleOnce sync.Once
 }
 
 // Creates a new logger. Configuration should be set by changing `Formatter`,
@@ -51,6 +72,32 @@ func New() *Logger {
 	}
 }
 
+// prepareConsole enables ANSI escape sequence interpretation on Windows
+// console handles so that TextFormatter's colored output works the same way
+// it already does on Unix. It's a no-op when Out isn't os.Stdout/os.Stderr.
+// When Out is one of them but can't be switched into virtual terminal mode,
+// either because it isn't attached to a console at all (e.g. it was
+// redirected to a file or piped) or because the console predates Windows
+// 10, a TextFormatter's colors are disabled instead of leaking raw escape
+// codes into the output.
+//
+// This runs lazily, once, from the first call to write rather than from
+// New(), since New's own doc comment above also allows building a Logger as
+// a struct literal instead of calling New() — a construction style that
+// would otherwise get no Windows color support at all.
+func (logger *Logger) prepareConsole() {
+	file, ok := logger.Out.(*os.File)
+	if !ok || (file != os.Stdout && file != os.Stderr) {
+		return
+	}
+
+	if !enableColorConsole(file) {
+		if formatter, ok := logger.Formatter.(*TextFormatter); ok {
+			formatter.DisableColors = true
+		}
+	}
+}
+
 // Reports whether log level is at least debug level.
 //
 // This method can be used to prevent evaluation of arguments if
@@ -176,10 +223,12 @@ func (logger *Logger) Errorf(format string, args ...interface{}) {
 }
 
 func (logger *Logger) Fatalf(format string, args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Fatalf(format, args...)
 }
 
 func (logger *Logger) Panicf(format string, args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Panicf(format, args...)
 }
 
@@ -208,10 +257,12 @@ func (logger *Logger) Error(args ...interface{}) {
 }
 
 func (logger *Logger) Fatal(args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Fatal(args...)
 }
 
 func (logger *Logger) Panic(args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Panic(args...)
 }
 
@@ -240,9 +291,11 @@ func (logger *Logger) Errorln(args ...interface{}) {
 }
 
 func (logger *Logger) Fatalln(args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Fatalln(args...)
 }
 
 func (logger *Logger) Panicln(args ...interface{}) {
+	logger.prepareSyncExit()
 	NewEntry(logger).Panicln(args...)
 }
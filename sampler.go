@@ -0,0 +1,175 @@
+package logrus
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sampler decides whether an Entry should continue on to Sink/Out. It's
+// consulted in the entry path before formatting/writing, so a Sampler that
+// returns false suppresses the entry entirely.
+type Sampler interface {
+	// Sample reports whether entry should be logged.
+	Sample(entry *Entry) bool
+}
+
+// tokenBucket is a plain token-bucket rate limiter: it starts full, refills
+// at rate tokens/sec up to burst, and each allow() call spends one token.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(ratePerSecond float64) *tokenBucket {
+	return &tokenBucket{
+		rate:   ratePerSecond,
+		burst:  ratePerSecond,
+		tokens: ratePerSecond,
+		last:   time.Now(),
+	}
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// LevelLimiter is a Sampler that rate-limits each Level independently, e.g.
+// 100 entries/sec at Info while leaving Error and above unlimited. A Level
+// missing from Limits is never suppressed.
+type LevelLimiter struct {
+	// Limits maps a Level to how many entries per second it may log.
+	Limits map[Level]float64
+
+	mu      sync.Mutex
+	buckets map[Level]*tokenBucket
+}
+
+// NewLevelLimiter builds a LevelLimiter from limits, a map of Level to
+// entries-per-second.
+func NewLevelLimiter(limits map[Level]float64) *LevelLimiter {
+	return &LevelLimiter{Limits: limits}
+}
+
+func (l *LevelLimiter) Sample(entry *Entry) bool {
+	rate, ok := l.Limits[entry.Level]
+	if !ok {
+		return true
+	}
+
+	l.mu.Lock()
+	if l.buckets == nil {
+		l.buckets = make(map[Level]*tokenBucket)
+	}
+	bucket, ok := l.buckets[entry.Level]
+	if !ok {
+		bucket = newTokenBucket(rate)
+		l.buckets[entry.Level] = bucket
+	}
+	l.mu.Unlock()
+
+	return bucket.allow()
+}
+
+// FirstNThenEveryM is a Sampler that lets the first N entries sharing a
+// fingerprint through, then every Mth one after that, thinning out repeated
+// messages instead of either keeping or dropping all of them.
+type FirstNThenEveryM struct {
+	N int
+	M int
+	// Fingerprint groups entries into the buckets N and M apply to. Defaults
+	// to the file:line of the log call (via runtime.Callers) when nil.
+	Fingerprint func(entry *Entry) string
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *FirstNThenEveryM) Sample(entry *Entry) bool {
+	fingerprint := s.Fingerprint
+	if fingerprint == nil {
+		fingerprint = callerFingerprint
+	}
+	key := fingerprint(entry)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.counts[key]++
+	count := s.counts[key]
+
+	if count <= s.N {
+		return true
+	}
+
+	// M <= 0 has no sensible "every Mth" meaning; treat it as "never again
+	// after the first N" instead of dividing by zero.
+	if s.M <= 0 {
+		return false
+	}
+
+	return (count-s.N)%s.M == 0
+}
+
+// logrusPackage is the package FirstNThenEveryM's default fingerprint walks
+// past to find the caller's own frame, the same way callers are attributed
+// elsewhere in logrus.
+var logrusPackage = getPackageName(func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	return runtime.FuncForPC(pc).Name()
+}())
+
+func getPackageName(f string) string {
+	for {
+		lastPeriod := strings.LastIndex(f, ".")
+		lastSlash := strings.LastIndex(f, "/")
+		if lastPeriod > lastSlash {
+			f = f[:lastPeriod]
+		} else {
+			break
+		}
+	}
+	return f
+}
+
+// callerFingerprint is the default Fingerprint for FirstNThenEveryM: the
+// file:line of the first frame above the logrus package itself.
+func callerFingerprint(entry *Entry) string {
+	pcs := make([]uintptr, 10)
+	depth := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:depth])
+
+	for {
+		frame, more := frames.Next()
+		if getPackageName(frame.Function) != logrusPackage {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return "unknown"
+}
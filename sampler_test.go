@@ -0,0 +1,94 @@
+package logrus
+
+import "testing"
+
+func TestLevelLimiterAllowsUnconfiguredLevels(t *testing.T) {
+	limiter := NewLevelLimiter(map[Level]float64{InfoLevel: 1})
+
+	for i := 0; i < 10; i++ {
+		if !limiter.Sample(&Entry{Level: ErrorLevel}) {
+			t.Fatal("LevelLimiter suppressed a level with no configured limit")
+		}
+	}
+}
+
+func TestLevelLimiterCapsBurstAtConfiguredRate(t *testing.T) {
+	limiter := NewLevelLimiter(map[Level]float64{InfoLevel: 3})
+
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if limiter.Sample(&Entry{Level: InfoLevel}) {
+			allowed++
+		}
+	}
+
+	if allowed != 3 {
+		t.Fatalf("allowed = %d, want 3 (the configured burst/rate)", allowed)
+	}
+}
+
+func TestFirstNThenEveryMLetsFirstNThrough(t *testing.T) {
+	sampler := &FirstNThenEveryM{N: 3, M: 2, Fingerprint: func(*Entry) string { return "key" }}
+
+	for i := 0; i < 3; i++ {
+		if !sampler.Sample(&Entry{}) {
+			t.Fatalf("entry %d of the first N was suppressed", i)
+		}
+	}
+}
+
+func TestFirstNThenEveryMThinsAfterN(t *testing.T) {
+	sampler := &FirstNThenEveryM{N: 2, M: 3, Fingerprint: func(*Entry) string { return "key" }}
+
+	var allowed []bool
+	for i := 0; i < 8; i++ {
+		allowed = append(allowed, sampler.Sample(&Entry{}))
+	}
+
+	// First 2 (N) always pass, then every 3rd (M) after that: counts 3,4,5,6,7,8 -> allow at 5, 8.
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i := range want {
+		if allowed[i] != want[i] {
+			t.Fatalf("allowed = %v, want %v", allowed, want)
+		}
+	}
+}
+
+func TestFirstNThenEveryMTracksFingerprintsIndependently(t *testing.T) {
+	calls := map[string]int{"a": 0, "b": 0}
+	sampler := &FirstNThenEveryM{N: 1, M: 100, Fingerprint: func(entry *Entry) string {
+		return entry.Message
+	}}
+
+	for i := 0; i < 5; i++ {
+		calls["a"] += boolToInt(sampler.Sample(&Entry{Message: "a"}))
+		calls["b"] += boolToInt(sampler.Sample(&Entry{Message: "b"}))
+	}
+
+	if calls["a"] != 1 || calls["b"] != 1 {
+		t.Fatalf("calls = %v, want 1 pass each (N=1) since fingerprints are tracked separately", calls)
+	}
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func TestFirstNThenEveryMZeroMNeverPanicsAndStopsAfterN(t *testing.T) {
+	sampler := &FirstNThenEveryM{N: 2, Fingerprint: func(*Entry) string { return "key" }} // M left at zero value
+
+	for i := 0; i < 2; i++ {
+		if !sampler.Sample(&Entry{}) {
+			t.Fatalf("entry %d of the first N was suppressed", i)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		if sampler.Sample(&Entry{}) {
+			t.Fatal("entry past N was let through despite M<=0")
+		}
+	}
+}
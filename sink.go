@@ -0,0 +1,104 @@
+package logrus
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Sink is a structured logging destination that consumes a fully-populated
+// Entry directly, instead of forcing it through an io.Writer byte stream.
+// It's the right fit for destinations that have no use for pre-serialized
+// bytes, e.g. a Kafka producer, an HTTP log shipper, or an in-memory ring
+// buffer used by tests.
+type Sink interface {
+	// Write hands entry to the sink. Level, Time, Data and Message are all
+	// populated by the time this is called.
+	Write(entry *Entry) error
+	// Close releases any resources held by the sink, e.g. flushing and
+	// closing an underlying connection.
+	Close() error
+}
+
+// writerSink adapts an io.Writer/Formatter pair into a Sink, which is how
+// Logger falls back to its Out/Formatter fields when Sink is nil.
+type writerSink struct {
+	out       io.Writer
+	formatter Formatter
+}
+
+// SinkFromWriter wraps out and formatter as a Sink, formatting each Entry
+// before writing the result to out.
+func SinkFromWriter(out io.Writer, formatter Formatter) Sink {
+	return &writerSink{out: out, formatter: formatter}
+}
+
+func (s *writerSink) Write(entry *Entry) error {
+	serialized, err := s.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.out.Write(serialized)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if closer, ok := s.out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// multiSink fans a single Entry out to several sinks.
+type multiSink struct {
+	sinks []Sink
+}
+
+// MultiSink returns a Sink that writes every Entry to each of sinks in
+// order, continuing past errors and returning the first one it saw.
+func MultiSink(sinks ...Sink) Sink {
+	return &multiSink{sinks: sinks}
+}
+
+func (s *multiSink) Write(entry *Entry) error {
+	var firstErr error
+	for _, sink := range s.sinks {
+		if err := sink.Write(entry); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *multiSink) Close() error {
+	var firstErr error
+	for _, sink := range s.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// write dispatches a fully-populated entry to Sink if one is configured,
+// falling back to formatting it through Formatter and writing the result to
+// Out. Entry.log calls this once it has set Level, Time and Message and
+// fired hooks. If Sampler is set and decides to suppress entry, nothing is
+// formatted or written at all.
+func (logger *Logger) write(entry *Entry) error {
+	logger.consoleOnce.Do(logger.prepareConsole)
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	if logger.Sampler != nil && !logger.Sampler.Sample(entry) {
+		atomic.AddUint64(&logger.sampled, 1)
+		return nil
+	}
+
+	sink := logger.Sink
+	if sink == nil {
+		sink = SinkFromWriter(logger.Out, logger.Formatter)
+	}
+
+	return sink.Write(entry)
+}
@@ -0,0 +1,126 @@
+package logrus
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// fakeFormatter renders an Entry's Message as-is, so tests don't need to
+// depend on TextFormatter/JSONFormatter's actual output.
+type fakeFormatter struct{}
+
+func (fakeFormatter) Format(entry *Entry) ([]byte, error) {
+	return []byte(entry.Message), nil
+}
+
+// closableBuffer records whether Close was called, so SinkFromWriter's
+// io.Closer passthrough can be verified.
+type closableBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closableBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestSinkFromWriterFormatsAndWrites(t *testing.T) {
+	var buf bytes.Buffer
+	sink := SinkFromWriter(&buf, fakeFormatter{})
+
+	if err := sink.Write(&Entry{Message: "hello"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if got := buf.String(); got != "hello" {
+		t.Fatalf("buf = %q, want %q", got, "hello")
+	}
+}
+
+func TestSinkFromWriterCloseClosesUnderlyingWriter(t *testing.T) {
+	buf := &closableBuffer{}
+	sink := SinkFromWriter(buf, fakeFormatter{})
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !buf.closed {
+		t.Fatal("SinkFromWriter.Close() didn't close the underlying io.Writer")
+	}
+}
+
+// recordingSink counts writes/closes and can be made to fail either.
+type recordingSink struct {
+	writes   int
+	closes   int
+	writeErr error
+	closeErr error
+}
+
+func (s *recordingSink) Write(entry *Entry) error {
+	s.writes++
+	return s.writeErr
+}
+
+func (s *recordingSink) Close() error {
+	s.closes++
+	return s.closeErr
+}
+
+func TestMultiSinkFansOutToEverySink(t *testing.T) {
+	a, b := &recordingSink{}, &recordingSink{}
+	sink := MultiSink(a, b)
+
+	if err := sink.Write(&Entry{Message: "x"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if a.writes != 1 || b.writes != 1 {
+		t.Fatalf("writes = %d, %d, want 1, 1", a.writes, b.writes)
+	}
+}
+
+func TestMultiSinkReturnsFirstErrorButKeepsGoing(t *testing.T) {
+	errA := errors.New("a failed")
+	a := &recordingSink{writeErr: errA}
+	b := &recordingSink{}
+	sink := MultiSink(a, b)
+
+	if err := sink.Write(&Entry{Message: "x"}); err != errA {
+		t.Fatalf("Write error = %v, want %v", err, errA)
+	}
+	if b.writes != 1 {
+		t.Fatal("MultiSink stopped fanning out after the first sink's error")
+	}
+}
+
+func TestMultiSinkCloseClosesEverySink(t *testing.T) {
+	a, b := &recordingSink{}, &recordingSink{}
+	sink := MultiSink(a, b)
+
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if a.closes != 1 || b.closes != 1 {
+		t.Fatalf("closes = %d, %d, want 1, 1", a.closes, b.closes)
+	}
+}
+
+func TestLoggerWritePrefersSinkOverOut(t *testing.T) {
+	var buf bytes.Buffer
+	sink := &recordingSink{}
+	logger := &Logger{Out: &buf, Formatter: fakeFormatter{}, Sink: sink}
+
+	if err := logger.write(&Entry{Message: "hi"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if sink.writes != 1 {
+		t.Fatal("Logger.write didn't use Sink even though it was set")
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Logger.write also wrote to Out: %q", buf.String())
+	}
+}